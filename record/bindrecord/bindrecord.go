@@ -0,0 +1,144 @@
+// Package bindrecord lets a record.Record be declared purely with struct
+// tags, instead of requiring hand-written MarshalRecord/UnmarshalRecord
+// methods for every small schema a project defines.
+//
+// A type opts in by tagging one of its fields (conventionally a blank
+// identifier field) with the payload type it should be registered under and,
+// optionally, which Codec to use:
+//
+//    type HelloRecord struct {
+//        _       struct{} `record:"payloadType=/libp2p/hello-record,codec=json"`
+//        Message string
+//    }
+//
+//    func init() {
+//        if err := bindrecord.Register(&HelloRecord{}); err != nil {
+//            panic(err)
+//        }
+//    }
+//
+// Register synthesizes a Bound[HelloRecord] and installs it in
+// record.DefaultRegistry for that payload type. Consumers that open an
+// Envelope carrying that PayloadType get back a *Bound[HelloRecord] whose
+// Inner field holds the decoded value.
+package bindrecord
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+	"sync"
+
+	"github.com/libp2p/go-libp2p-core/record"
+)
+
+// tagKey is the struct tag key Register looks for.
+const tagKey = "record"
+
+// Bound adapts T to the record.Record interface, marshaling and
+// unmarshaling Inner through the Codec that was registered for T by
+// Register. Most callers never construct a Bound directly; it's returned by
+// the record registry for payload types that were registered via Register.
+type Bound[T any] struct {
+	Inner T
+}
+
+// MarshalRecord implements record.Record.
+func (b *Bound[T]) MarshalRecord() ([]byte, error) {
+	codec, ok := codecForType(reflect.TypeOf(b.Inner))
+	if !ok {
+		return nil, fmt.Errorf("bindrecord: no codec registered for %T, was it registered with Register?", b.Inner)
+	}
+	return codec.Marshal(b.Inner)
+}
+
+// UnmarshalRecord implements record.Record.
+func (b *Bound[T]) UnmarshalRecord(data []byte) error {
+	codec, ok := codecForType(reflect.TypeOf(b.Inner))
+	if !ok {
+		return fmt.Errorf("bindrecord: no codec registered for %T, was it registered with Register?", b.Inner)
+	}
+	return codec.Unmarshal(data, &b.Inner)
+}
+
+// Register declares T as a Record type on record.DefaultRegistry. It is
+// equivalent to RegisterOn(record.DefaultRegistry, v).
+func Register[T any](v *T) error {
+	return RegisterOn(record.DefaultRegistry, v)
+}
+
+// RegisterOn declares T as a Record type by reading the `record:"..."`
+// struct tag on one of its fields, instead of requiring T to implement
+// MarshalRecord/UnmarshalRecord by hand. The tagged field must set
+// payloadType and may set codec (default "json"); see the package doc for
+// an example.
+//
+// RegisterOn installs a Bound[T] in reg under the declared payload type, so
+// it should be called from an init function the same way reg.Register is.
+// Passing a caller-constructed reg (instead of using Register, which always
+// targets record.DefaultRegistry) lets bound Record types live on an
+// isolated record.Registry the same as hand-written ones.
+func RegisterOn[T any](reg *record.Registry, _ *T) error {
+	var zero T
+	structType := reflect.TypeOf(zero)
+	if structType == nil || structType.Kind() != reflect.Struct {
+		return fmt.Errorf("bindrecord: Register requires a struct type, got %T", zero)
+	}
+
+	payloadType, codecName, err := parseTag(structType)
+	if err != nil {
+		return err
+	}
+
+	codec, ok := codecNamed(codecName)
+	if !ok {
+		return fmt.Errorf("bindrecord: %s names unknown codec %q, register it first with RegisterCodec", structType, codecName)
+	}
+
+	typeCodecsMu.Lock()
+	typeCodecs[structType] = codec
+	typeCodecsMu.Unlock()
+
+	reg.Register(record.PayloadTypeFromString(payloadType), &Bound[T]{})
+	return nil
+}
+
+func parseTag(t reflect.Type) (payloadType, codecName string, err error) {
+	for i := 0; i < t.NumField(); i++ {
+		tag, ok := t.Field(i).Tag.Lookup(tagKey)
+		if !ok {
+			continue
+		}
+
+		for _, part := range strings.Split(tag, ",") {
+			kv := strings.SplitN(part, "=", 2)
+			if len(kv) != 2 {
+				return "", "", fmt.Errorf("bindrecord: %s.%s has a malformed %q tag entry %q", t, t.Field(i).Name, tagKey, part)
+			}
+			switch strings.TrimSpace(kv[0]) {
+			case "payloadType":
+				payloadType = kv[1]
+			case "codec":
+				codecName = kv[1]
+			}
+		}
+		if payloadType == "" {
+			return "", "", fmt.Errorf("bindrecord: %s.%s has a %q tag with no payloadType", t, t.Field(i).Name, tagKey)
+		}
+		if codecName == "" {
+			codecName = "json"
+		}
+		return payloadType, codecName, nil
+	}
+	return "", "", fmt.Errorf("bindrecord: %s has no field tagged %q", t, tagKey)
+}
+
+var typeCodecsMu sync.RWMutex
+var typeCodecs = make(map[reflect.Type]Codec)
+
+func codecForType(t reflect.Type) (Codec, bool) {
+	typeCodecsMu.RLock()
+	defer typeCodecsMu.RUnlock()
+	c, ok := typeCodecs[t]
+	return c, ok
+}
@@ -0,0 +1,83 @@
+package bindrecord
+
+import (
+	"testing"
+
+	"github.com/libp2p/go-libp2p-core/record"
+)
+
+type helloRecord struct {
+	_       struct{} `record:"payloadType=/test/bindrecord/hello,codec=json"`
+	Message string
+}
+
+type untaggedRecord struct {
+	Message string
+}
+
+type noPayloadTypeRecord struct {
+	_       struct{} `record:"codec=json"`
+	Message string
+}
+
+type unknownCodecRecord struct {
+	_       struct{} `record:"payloadType=/test/bindrecord/unknown-codec,codec=protobuf"`
+	Message string
+}
+
+func TestRegisterOnRoundTrip(t *testing.T) {
+	reg := &record.Registry{}
+
+	if err := RegisterOn(reg, &helloRecord{}); err != nil {
+		t.Fatalf("RegisterOn returned unexpected error: %s", err)
+	}
+
+	pt := record.PayloadTypeFromString("/test/bindrecord/hello")
+	if !reg.PayloadTypeRegistered(pt) {
+		t.Fatalf("expected %q to be registered on reg", pt)
+	}
+
+	bound := &Bound[helloRecord]{Inner: helloRecord{Message: "hi"}}
+	data, err := bound.MarshalRecord()
+	if err != nil {
+		t.Fatalf("MarshalRecord returned unexpected error: %s", err)
+	}
+
+	rec, err := reg.UnmarshalRecordPayload(pt, data)
+	if err != nil {
+		t.Fatalf("UnmarshalRecordPayload returned unexpected error: %s", err)
+	}
+	got, ok := rec.(*Bound[helloRecord])
+	if !ok {
+		t.Fatalf("expected *Bound[helloRecord], got %T", rec)
+	}
+	if got.Inner.Message != "hi" {
+		t.Fatalf("expected round-tripped Message %q, got %q", "hi", got.Inner.Message)
+	}
+}
+
+func TestRegisterOnDoesNotLeakOntoOtherRegistries(t *testing.T) {
+	reg := &record.Registry{}
+	other := &record.Registry{}
+
+	if err := RegisterOn(reg, &helloRecord{}); err != nil {
+		t.Fatalf("RegisterOn returned unexpected error: %s", err)
+	}
+
+	pt := record.PayloadTypeFromString("/test/bindrecord/hello")
+	if other.PayloadTypeRegistered(pt) {
+		t.Fatalf("expected %q registered via RegisterOn(reg, ...) not to be visible on an unrelated registry", pt)
+	}
+}
+
+func TestRegisterOnTagParseFailures(t *testing.T) {
+	if err := RegisterOn(&record.Registry{}, &untaggedRecord{}); err == nil {
+		t.Fatalf("expected an error for a struct with no record tag")
+	}
+	if err := RegisterOn(&record.Registry{}, &noPayloadTypeRecord{}); err == nil {
+		t.Fatalf("expected an error for a record tag missing payloadType")
+	}
+	if err := RegisterOn(&record.Registry{}, &unknownCodecRecord{}); err == nil {
+		t.Fatalf("expected an error for a record tag naming an unregistered codec")
+	}
+}
@@ -0,0 +1,46 @@
+package bindrecord
+
+import (
+	"encoding/json"
+	"sync"
+)
+
+// Codec marshals and unmarshals the value bound by a Bound[T] to and from
+// bytes. Implementations are expected to round-trip through v's exported
+// fields, the same way encoding/json does.
+type Codec interface {
+	Marshal(v interface{}) ([]byte, error)
+	Unmarshal(data []byte, v interface{}) error
+}
+
+// RegisterCodec makes a Codec available under name for use in a `codec=name`
+// struct tag entry. Projects that want to bind Records through CBOR or
+// protobuf instead of the built-in "json" codec should call this from an
+// init function before calling Register.
+func RegisterCodec(name string, c Codec) {
+	codecsMu.Lock()
+	defer codecsMu.Unlock()
+	codecs[name] = c
+}
+
+func codecNamed(name string) (Codec, bool) {
+	codecsMu.RLock()
+	defer codecsMu.RUnlock()
+	c, ok := codecs[name]
+	return c, ok
+}
+
+var codecsMu sync.RWMutex
+var codecs = map[string]Codec{
+	"json": jsonCodec{},
+}
+
+type jsonCodec struct{}
+
+func (jsonCodec) Marshal(v interface{}) ([]byte, error) {
+	return json.Marshal(v)
+}
+
+func (jsonCodec) Unmarshal(data []byte, v interface{}) error {
+	return json.Unmarshal(data, v)
+}
@@ -0,0 +1,45 @@
+package record
+
+import (
+	"encoding/binary"
+	"fmt"
+)
+
+// identityMulticodec is the multicodec code for raw, unprocessed bytes
+// (https://github.com/multiformats/multicodec/blob/master/table.csv). It is
+// used by PayloadTypeFromString to mark a PayloadType whose suffix is an
+// opaque, human-readable path rather than a codec-specific encoding.
+const identityMulticodec = 0x00
+
+// PayloadType is a binary Envelope.PayloadType identifier. A well-formed
+// PayloadType is a varint-encoded multicodec code followed by a
+// codec-specific suffix, making it self-describing: given only the bytes of
+// a PayloadType, a consumer can recover which multicodec produced it via
+// Codec, even if it doesn't recognize the Record type registered for it.
+type PayloadType []byte
+
+// PayloadTypeFromCodec builds a PayloadType by varint-encoding code as a
+// multicodec prefix and appending suffix.
+func PayloadTypeFromCodec(code uint64, suffix []byte) PayloadType {
+	buf := make([]byte, binary.MaxVarintLen64)
+	n := binary.PutUvarint(buf, code)
+	return PayloadType(append(buf[:n:n], suffix...))
+}
+
+// PayloadTypeFromString builds a PayloadType for the common case of a
+// human-readable path, such as "/libp2p/hello-record", prefixed with the
+// identity multicodec.
+func PayloadTypeFromString(path string) PayloadType {
+	return PayloadTypeFromCodec(identityMulticodec, []byte(path))
+}
+
+// Codec decodes the multicodec prefix of the PayloadType, returning the
+// multicodec code and the remaining suffix. It returns an error if pt is
+// empty or does not begin with a valid varint.
+func (pt PayloadType) Codec() (code uint64, suffix []byte, err error) {
+	code, n := binary.Uvarint(pt)
+	if n <= 0 {
+		return 0, nil, fmt.Errorf("record: PayloadType %q is missing a multicodec prefix", []byte(pt))
+	}
+	return code, pt[n:], nil
+}
@@ -0,0 +1,84 @@
+package record
+
+import "testing"
+
+func TestPayloadTypeFromCodecRoundTrip(t *testing.T) {
+	pt := PayloadTypeFromCodec(0x71, []byte("suffix"))
+
+	code, suffix, err := pt.Codec()
+	if err != nil {
+		t.Fatalf("Codec() returned unexpected error: %s", err)
+	}
+	if code != 0x71 {
+		t.Fatalf("expected code 0x71, got %#x", code)
+	}
+	if string(suffix) != "suffix" {
+		t.Fatalf("expected suffix %q, got %q", "suffix", suffix)
+	}
+}
+
+func TestPayloadTypeFromStringUsesIdentityMulticodec(t *testing.T) {
+	pt := PayloadTypeFromString("/libp2p/hello-record")
+
+	code, suffix, err := pt.Codec()
+	if err != nil {
+		t.Fatalf("Codec() returned unexpected error: %s", err)
+	}
+	if code != identityMulticodec {
+		t.Fatalf("expected identity multicodec, got %#x", code)
+	}
+	if string(suffix) != "/libp2p/hello-record" {
+		t.Fatalf("expected suffix %q, got %q", "/libp2p/hello-record", suffix)
+	}
+}
+
+func TestPayloadTypeCodecRejectsMalformedInput(t *testing.T) {
+	cases := []struct {
+		name string
+		pt   PayloadType
+	}{
+		{"empty", PayloadType{}},
+		{"lone continuation byte", PayloadType{0x80}},
+		{"truncated multi-byte", PayloadType{0x80, 0x80}},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			if _, _, err := c.pt.Codec(); err == nil {
+				t.Fatalf("expected Codec() to fail for %q", []byte(c.pt))
+			}
+		})
+	}
+}
+
+func TestBlankRecordForPayloadTypeMalformedCodecIsNil(t *testing.T) {
+	reg := &Registry{}
+
+	rec, err := reg.BlankRecordForPayloadType(PayloadType{0x80})
+	if err != nil {
+		t.Fatalf("BlankRecordForPayloadType returned unexpected error: %s", err)
+	}
+	dr, ok := rec.(*DefaultRecord)
+	if !ok {
+		t.Fatalf("expected *DefaultRecord, got %T", rec)
+	}
+	if dr.Codec != nil {
+		t.Fatalf("expected Codec to be nil for a malformed PayloadType, got %d", *dr.Codec)
+	}
+}
+
+func TestBlankRecordForPayloadTypeWellFormedCodecIsSet(t *testing.T) {
+	reg := &Registry{}
+
+	rec, err := reg.BlankRecordForPayloadType(PayloadTypeFromCodec(0x71, nil))
+	if err != nil {
+		t.Fatalf("BlankRecordForPayloadType returned unexpected error: %s", err)
+	}
+	dr, ok := rec.(*DefaultRecord)
+	if !ok {
+		t.Fatalf("expected *DefaultRecord, got %T", rec)
+	}
+	if dr.Codec == nil || *dr.Codec != 0x71 {
+		t.Fatalf("expected Codec to be 0x71, got %v", dr.Codec)
+	}
+}
@@ -2,8 +2,6 @@ package record
 
 import "reflect"
 
-var payloadTypeRegistry = make(map[string]reflect.Type)
-
 // Record represents a data type that can be used as the payload of an Envelope.
 // The Record interface defines the methods used to marshal and unmarshal a Record
 // type to a byte slice.
@@ -25,9 +23,16 @@ type Record interface {
 
 // DefaultRecord contains the payload of an Envelope whose PayloadType field
 // does not match any registered Record type. The Contents field contains
-// the unprocessed Envelope payload.
+// the unprocessed Envelope payload. Codec holds the multicodec decoded from
+// the Envelope's PayloadType, so that consumers can still route an
+// otherwise-unrecognized payload by its encoding. Codec is nil when the
+// PayloadType was empty or did not carry a well-formed multicodec prefix
+// (see PayloadType.Codec) — callers must check for nil rather than treating
+// the zero multicodec code as a decoded value, since 0 is itself a valid
+// multicodec (identity).
 type DefaultRecord struct {
 	Contents []byte
+	Codec    *uint64
 }
 
 func (r *DefaultRecord) MarshalRecord() ([]byte, error) {
@@ -40,65 +45,6 @@ func (r *DefaultRecord) UnmarshalRecord(data []byte) error {
 	return nil
 }
 
-// RegisterPayloadType associates a binary payload type identifier with a concrete
-// Record type. This is used to automatically unmarshal Record payloads from Envelopes
-// when using ConsumeEnvelope, and to automatically marshal Records and determine the
-// correct PayloadType when calling MakeEnvelopeWithRecord.
-//
-// To register a Record type, provide the payload type identifier and an
-// empty instance of the Record type.
-//
-// Registration should be done in the init function of the package where the
-// Record type is defined:
-//
-//    package hello_record
-//
-//    var HelloRecordPayloadType = []byte("/libp2p/hello-record")
-//
-//    func init() {
-//        RegisterPayloadType(HelloRecordPayloadType, &HelloRecord{})
-//    }
-//
-//    type HelloRecord struct { } // etc..
-//
-func RegisterPayloadType(payloadType []byte, prototype Record) {
-	payloadTypeRegistry[string(payloadType)] = getValueType(prototype)
-}
-
-func unmarshalRecordPayload(payloadType []byte, payloadBytes []byte) (Record, error) {
-	rec, err := blankRecordForPayloadType(payloadType)
-	if err != nil {
-		return nil, err
-	}
-	err = rec.UnmarshalRecord(payloadBytes)
-	if err != nil {
-		return nil, err
-	}
-	return rec, nil
-}
-
-func blankRecordForPayloadType(payloadType []byte) (Record, error) {
-	valueType, ok := payloadTypeRegistry[string(payloadType)]
-	if !ok {
-		return &DefaultRecord{}, nil
-	}
-
-	val := reflect.New(valueType)
-	asRecord := val.Interface().(Record)
-	return asRecord, nil
-}
-
-func payloadTypeForRecord(rec Record) ([]byte, bool) {
-	valueType := getValueType(rec)
-
-	for k, t := range payloadTypeRegistry {
-		if t.AssignableTo(valueType) {
-			return []byte(k), true
-		}
-	}
-	return []byte{}, false
-}
-
 func getValueType(i interface{}) reflect.Type {
 	valueType := reflect.TypeOf(i)
 	if valueType.Kind() == reflect.Ptr {
@@ -0,0 +1,230 @@
+package record
+
+import (
+	"fmt"
+	"reflect"
+	"sync"
+)
+
+// Registry maps Envelope.PayloadType identifiers to the concrete Record
+// type that should be used to unmarshal a payload of that type.
+//
+// A Registry is safe for concurrent use. The zero value is a valid, empty
+// Registry. Most applications don't need to construct their own Registry
+// and can use the package-level functions (RegisterPayloadType, etc.),
+// which operate on DefaultRegistry. Constructing a dedicated Registry is
+// useful for applications that embed multiple libp2p stacks with
+// different Record vocabularies, or for tests that want to register and
+// tear down Record types without affecting global state.
+type Registry struct {
+	mu    sync.RWMutex
+	types map[string]reflect.Type
+
+	// aliases maps a legacy payload type identifier to the Go type it used
+	// to be decoded as and the migrator (if any) that upgrades a decoded
+	// instance of that legacy type to the current one. Populated by
+	// RegisterVersioned.
+	aliases map[string]aliasEntry
+}
+
+type aliasEntry struct {
+	valueType reflect.Type
+	migrate   func(Record) (Record, error)
+}
+
+// DefaultRegistry is the Registry consulted by the package-level
+// RegisterPayloadType, UnregisterPayloadType, PayloadTypeRegistered, and
+// RegisteredPayloadTypes functions.
+//
+// This package does not currently define Envelope or ConsumeEnvelope; once
+// they exist, ConsumeEnvelope should take an optional *Registry argument
+// and fall back to DefaultRegistry when none is given, so that callers
+// holding an isolated Registry aren't forced back onto global state to
+// open an Envelope.
+var DefaultRegistry = &Registry{}
+
+// Register associates a PayloadType identifier with a concrete Record type.
+// UnmarshalRecordPayload and BlankRecordForPayloadType use this association
+// to pick the Record type for a payload, and PayloadTypeForRecord uses it to
+// find the PayloadType registered for a Record.
+//
+// To register a Record type, provide the payload type identifier and an
+// empty instance of the Record type.
+//
+// Register panics if payloadType is empty or does not carry a well-formed
+// multicodec prefix (see PayloadType.Codec), and if payloadType is already
+// registered to a different concrete Record type. Registering the same
+// payload type identifier to the same Record type more than once is a
+// no-op, which makes it safe to call from the init function of a package
+// that may be imported multiple times.
+//
+// Registration should be done in the init function of the package where the
+// Record type is defined:
+//
+//    package hello_record
+//
+//    var HelloRecordPayloadType = record.PayloadTypeFromString("/libp2p/hello-record")
+//
+//    func init() {
+//        record.DefaultRegistry.Register(HelloRecordPayloadType, &HelloRecord{})
+//    }
+//
+//    type HelloRecord struct { } // etc..
+//
+func (r *Registry) Register(payloadType PayloadType, prototype Record) {
+	if _, _, err := payloadType.Codec(); err != nil {
+		panic(fmt.Sprintf("record: cannot register payload type: %s", err))
+	}
+	valueType := getValueType(prototype)
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.types == nil {
+		r.types = make(map[string]reflect.Type)
+	}
+	if existing, ok := r.types[string(payloadType)]; ok && existing != valueType {
+		panic(fmt.Sprintf("payload type %q is already registered to %s, cannot register %s", payloadType, existing, valueType))
+	}
+	r.types[string(payloadType)] = valueType
+}
+
+// Unregister removes the Record type associated with the given payload type
+// identifier, if any. This is mainly useful for tests that register
+// throwaway Record types and want to clean up after themselves.
+func (r *Registry) Unregister(payloadType PayloadType) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	delete(r.types, string(payloadType))
+}
+
+// PayloadTypeRegistered returns true if a Record type has been registered
+// for the given payload type identifier.
+func (r *Registry) PayloadTypeRegistered(payloadType PayloadType) bool {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	_, ok := r.types[string(payloadType)]
+	return ok
+}
+
+// RegisteredPayloadTypes returns the payload type identifiers of all
+// currently registered Record types.
+func (r *Registry) RegisteredPayloadTypes() []PayloadType {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	out := make([]PayloadType, 0, len(r.types))
+	for k := range r.types {
+		out = append(out, PayloadType(k))
+	}
+	return out
+}
+
+// UnmarshalRecordPayload unmarshals the given bytes into the Record type
+// registered for the given payload type identifier. If no Record type is
+// registered for the identifier, the bytes are unmarshaled into a
+// DefaultRecord. If payloadType is a legacy identifier registered as an
+// alias via WithAliases, the bytes are unmarshaled using that alias's own
+// Record type, and the result is passed through the migrator (if any,
+// attached via WithMigrator) before being returned.
+func (r *Registry) UnmarshalRecordPayload(payloadType PayloadType, payloadBytes []byte) (Record, error) {
+	rec, err := r.BlankRecordForPayloadType(payloadType)
+	if err != nil {
+		return nil, err
+	}
+	if err := rec.UnmarshalRecord(payloadBytes); err != nil {
+		return nil, err
+	}
+
+	r.mu.RLock()
+	entry, aliased := r.aliases[string(payloadType)]
+	r.mu.RUnlock()
+	if aliased && entry.migrate != nil {
+		return entry.migrate(rec)
+	}
+	return rec, nil
+}
+
+// BlankRecordForPayloadType returns a zero-value instance of the Record type
+// registered for the given payload type identifier. If payloadType is a
+// legacy identifier registered as an alias via WithAliases, the returned
+// instance is of that alias's own Record type, not the current one. If no
+// Record type is registered for it at all, it falls back to a
+// *DefaultRecord whose Codec field holds the multicodec decoded from
+// payloadType, or nil if payloadType is not well-formed, so callers can
+// still route the otherwise-unknown payload.
+func (r *Registry) BlankRecordForPayloadType(payloadType PayloadType) (Record, error) {
+	r.mu.RLock()
+	valueType, ok := r.types[string(payloadType)]
+	if !ok {
+		if entry, aliased := r.aliases[string(payloadType)]; aliased {
+			valueType, ok = entry.valueType, true
+		}
+	}
+	r.mu.RUnlock()
+	if !ok {
+		var codec *uint64
+		if code, _, err := payloadType.Codec(); err == nil {
+			codec = &code
+		}
+		return &DefaultRecord{Codec: codec}, nil
+	}
+
+	val := reflect.New(valueType)
+	asRecord := val.Interface().(Record)
+	return asRecord, nil
+}
+
+// PayloadTypeForRecord returns the payload type identifier registered for
+// rec's concrete type, and false if rec's type is not registered.
+func (r *Registry) PayloadTypeForRecord(rec Record) (PayloadType, bool) {
+	valueType := getValueType(rec)
+
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	for k, t := range r.types {
+		if t.AssignableTo(valueType) {
+			return PayloadType(k), true
+		}
+	}
+	return nil, false
+}
+
+// RegisterPayloadType registers prototype as the Record type for
+// payloadType on DefaultRegistry. See (*Registry).Register for details.
+func RegisterPayloadType(payloadType PayloadType, prototype Record) {
+	DefaultRegistry.Register(payloadType, prototype)
+}
+
+// UnregisterPayloadType removes the Record type associated with the given
+// payload type identifier from DefaultRegistry, if any.
+func UnregisterPayloadType(payloadType PayloadType) {
+	DefaultRegistry.Unregister(payloadType)
+}
+
+// PayloadTypeRegistered returns true if a Record type has been registered
+// for the given payload type identifier on DefaultRegistry.
+func PayloadTypeRegistered(payloadType PayloadType) bool {
+	return DefaultRegistry.PayloadTypeRegistered(payloadType)
+}
+
+// RegisteredPayloadTypes returns the payload type identifiers of all Record
+// types currently registered on DefaultRegistry.
+func RegisteredPayloadTypes() []PayloadType {
+	return DefaultRegistry.RegisteredPayloadTypes()
+}
+
+func unmarshalRecordPayload(payloadType PayloadType, payloadBytes []byte) (Record, error) {
+	return DefaultRegistry.UnmarshalRecordPayload(payloadType, payloadBytes)
+}
+
+func blankRecordForPayloadType(payloadType PayloadType) (Record, error) {
+	return DefaultRegistry.BlankRecordForPayloadType(payloadType)
+}
+
+func payloadTypeForRecord(rec Record) (PayloadType, bool) {
+	return DefaultRegistry.PayloadTypeForRecord(rec)
+}
@@ -0,0 +1,39 @@
+package record
+
+import "testing"
+
+// TestRegistriesAreIsolated verifies the premise of per-instance Registry:
+// registering a Record type on one Registry must not be visible on another,
+// independently constructed Registry.
+func TestRegistriesAreIsolated(t *testing.T) {
+	a := &Registry{}
+	b := &Registry{}
+	pt := PayloadTypeFromString("/test/isolated")
+
+	a.Register(pt, &fooRecord{})
+
+	if !a.PayloadTypeRegistered(pt) {
+		t.Fatalf("expected %q to be registered on a", pt)
+	}
+	if b.PayloadTypeRegistered(pt) {
+		t.Fatalf("expected %q NOT to be registered on the independent registry b", pt)
+	}
+}
+
+// TestPackageLevelFunctionsUseDefaultRegistry verifies that the
+// package-level RegisterPayloadType/PayloadTypeRegistered helpers and
+// DefaultRegistry observe each other's state, since they're documented as
+// thin wrappers over the same Registry.
+func TestPackageLevelFunctionsUseDefaultRegistry(t *testing.T) {
+	pt := PayloadTypeFromString("/test/default-registry")
+	defer UnregisterPayloadType(pt)
+
+	RegisterPayloadType(pt, &fooRecord{})
+
+	if !DefaultRegistry.PayloadTypeRegistered(pt) {
+		t.Fatalf("expected %q registered via RegisterPayloadType to be visible on DefaultRegistry", pt)
+	}
+	if !PayloadTypeRegistered(pt) {
+		t.Fatalf("expected PayloadTypeRegistered to observe the registration of %q made via RegisterPayloadType", pt)
+	}
+}
@@ -0,0 +1,130 @@
+package record
+
+import (
+	"sync"
+	"testing"
+)
+
+type fooRecord struct{ Value string }
+
+func (r *fooRecord) MarshalRecord() ([]byte, error) { return []byte(r.Value), nil }
+func (r *fooRecord) UnmarshalRecord(b []byte) error { r.Value = string(b); return nil }
+
+type barRecord struct{ Value string }
+
+func (r *barRecord) MarshalRecord() ([]byte, error) { return []byte(r.Value), nil }
+func (r *barRecord) UnmarshalRecord(b []byte) error { r.Value = string(b); return nil }
+
+func TestRegisterUnregisterPayloadType(t *testing.T) {
+	reg := &Registry{}
+	pt := PayloadTypeFromString("/test/foo")
+
+	if reg.PayloadTypeRegistered(pt) {
+		t.Fatalf("expected %q not to be registered yet", pt)
+	}
+
+	reg.Register(pt, &fooRecord{})
+	if !reg.PayloadTypeRegistered(pt) {
+		t.Fatalf("expected %q to be registered", pt)
+	}
+
+	found := false
+	for _, got := range reg.RegisteredPayloadTypes() {
+		if string(got) == string(pt) {
+			found = true
+		}
+	}
+	if !found {
+		t.Fatalf("expected %q in RegisteredPayloadTypes, got %v", pt, reg.RegisteredPayloadTypes())
+	}
+
+	reg.Unregister(pt)
+	if reg.PayloadTypeRegistered(pt) {
+		t.Fatalf("expected %q to be unregistered", pt)
+	}
+}
+
+func TestRegisterSameTypeTwiceIsNoop(t *testing.T) {
+	reg := &Registry{}
+	pt := PayloadTypeFromString("/test/foo")
+
+	reg.Register(pt, &fooRecord{})
+	reg.Register(pt, &fooRecord{}) // should not panic
+}
+
+func TestRegisterConflictingTypePanics(t *testing.T) {
+	reg := &Registry{}
+	pt := PayloadTypeFromString("/test/foo")
+	reg.Register(pt, &fooRecord{})
+
+	defer func() {
+		if recover() == nil {
+			t.Fatalf("expected Register to panic on conflicting payload type")
+		}
+	}()
+	reg.Register(pt, &barRecord{})
+}
+
+func TestRegisterRejectsMalformedPayloadType(t *testing.T) {
+	reg := &Registry{}
+
+	defer func() {
+		if recover() == nil {
+			t.Fatalf("expected Register to panic on a malformed payload type")
+		}
+	}()
+	reg.Register(PayloadType{0x80}, &fooRecord{})
+}
+
+func TestBlankRecordForPayloadTypeFallsBackToDefaultRecord(t *testing.T) {
+	reg := &Registry{}
+
+	rec, err := reg.BlankRecordForPayloadType(PayloadTypeFromString("/test/unknown"))
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if _, ok := rec.(*DefaultRecord); !ok {
+		t.Fatalf("expected *DefaultRecord, got %T", rec)
+	}
+}
+
+func TestUnmarshalRecordPayloadUsesRegisteredType(t *testing.T) {
+	reg := &Registry{}
+	pt := PayloadTypeFromString("/test/foo")
+	reg.Register(pt, &fooRecord{})
+
+	rec, err := reg.UnmarshalRecordPayload(pt, []byte("hello"))
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	foo, ok := rec.(*fooRecord)
+	if !ok {
+		t.Fatalf("expected *fooRecord, got %T", rec)
+	}
+	if foo.Value != "hello" {
+		t.Fatalf("expected Value %q, got %q", "hello", foo.Value)
+	}
+}
+
+// TestConcurrentRegisterAndLookup registers, looks up and unregisters payload
+// types from many goroutines at once; 64 goroutines share 26 payload types,
+// so the same type is registered concurrently. It is meant to be run with -race.
+func TestConcurrentRegisterAndLookup(t *testing.T) {
+	reg := &Registry{}
+	const n = 64
+
+	var wg sync.WaitGroup
+	wg.Add(n)
+	for i := 0; i < n; i++ {
+		i := i
+		go func() {
+			defer wg.Done()
+			pt := PayloadTypeFromString(string(rune('a' + i%26)))
+			reg.Register(pt, &fooRecord{})
+			reg.PayloadTypeRegistered(pt)
+			reg.RegisteredPayloadTypes()
+			reg.Unregister(pt)
+		}()
+	}
+	wg.Wait()
+}
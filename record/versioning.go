@@ -0,0 +1,100 @@
+package record
+
+import "fmt"
+
+// RegisterOption configures a RegisterVersioned call. See WithAliases and
+// WithMigrator.
+type RegisterOption func(*registerOptions)
+
+type registerOptions struct {
+	aliases  []Alias
+	migrator func(Record) (Record, error)
+}
+
+// Alias pairs a legacy PayloadType identifier with the Record type it used
+// to be decoded as, for use with WithAliases.
+type Alias struct {
+	PayloadType PayloadType
+	Prototype   Record
+}
+
+// WithAliases registers each given Alias as a legacy payload type
+// identifier for the Record type being registered. An Envelope carrying an
+// alias's PayloadType is unmarshaled using that alias's own Prototype type
+// (not the current one), instead of falling back to DefaultRecord, so a
+// migrator attached with WithMigrator can be handed a genuinely old-shaped
+// Record.
+func WithAliases(aliases ...Alias) RegisterOption {
+	return func(o *registerOptions) {
+		o.aliases = append(o.aliases, aliases...)
+	}
+}
+
+// WithMigrator attaches a function that transforms a Record decoded under
+// one of the aliases passed to WithAliases into the current version of that
+// Record. It runs once per UnmarshalRecordPayload call that resolves an
+// alias, after the aliased Prototype type has decoded the payload and
+// before the result is handed back to the caller. WithMigrator has no
+// effect unless used together with WithAliases.
+func WithMigrator(fn func(old Record) (Record, error)) RegisterOption {
+	return func(o *registerOptions) {
+		o.migrator = fn
+	}
+}
+
+// RegisterVersioned associates payloadType with prototype the same way
+// Register does, additionally recording any legacy payload type aliases and
+// migrator supplied via opts so that Records marshaled under a previous,
+// differently-shaped schema version can still be consumed.
+//
+//    var HelloRecordPayloadType = record.PayloadTypeFromString("/libp2p/hello-record/v2")
+//    var helloRecordPayloadTypeV1 = record.PayloadTypeFromString("/libp2p/hello-record")
+//
+//    func init() {
+//        record.DefaultRegistry.RegisterVersioned(HelloRecordPayloadType, &HelloRecord{},
+//            record.WithAliases(record.Alias{
+//                PayloadType: helloRecordPayloadTypeV1,
+//                Prototype:   &HelloRecordV1{},
+//            }),
+//            record.WithMigrator(func(old record.Record) (record.Record, error) {
+//                return upgradeHelloRecordV1(old.(*HelloRecordV1)), nil
+//            }),
+//        )
+//    }
+//
+func (r *Registry) RegisterVersioned(payloadType PayloadType, prototype Record, opts ...RegisterOption) {
+	var o registerOptions
+	for _, opt := range opts {
+		opt(&o)
+	}
+
+	r.Register(payloadType, prototype)
+
+	if len(o.aliases) == 0 {
+		return
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.aliases == nil {
+		r.aliases = make(map[string]aliasEntry)
+	}
+	for _, alias := range o.aliases {
+		if _, _, err := alias.PayloadType.Codec(); err != nil {
+			panic(fmt.Sprintf("record: cannot register alias: %s", err))
+		}
+		r.aliases[string(alias.PayloadType)] = aliasEntry{
+			valueType: getValueType(alias.Prototype),
+			migrate:   o.migrator,
+		}
+	}
+}
+
+// RegisterPayloadTypeVersioned registers prototype as the Record type for
+// payloadType on DefaultRegistry, with the legacy payload type aliases and
+// migrator described by opts. See (*Registry).RegisterVersioned for
+// details.
+func RegisterPayloadTypeVersioned(payloadType PayloadType, prototype Record, opts ...RegisterOption) {
+	DefaultRegistry.RegisterVersioned(payloadType, prototype, opts...)
+}
@@ -0,0 +1,112 @@
+package record
+
+import (
+	"errors"
+	"testing"
+)
+
+// helloV1 is a different Go shape from helloV2 (both encode the payload as
+// the same raw bytes): it decodes the payload into Text, not Message, so a
+// migrator is the only way to get from one to the other.
+type helloV1 struct{ Text string }
+
+func (r *helloV1) MarshalRecord() ([]byte, error) { return []byte(r.Text), nil }
+func (r *helloV1) UnmarshalRecord(b []byte) error  { r.Text = string(b); return nil }
+
+type helloV2 struct {
+	Message  string
+	Upgraded bool
+}
+
+func (r *helloV2) MarshalRecord() ([]byte, error) { return []byte(r.Message), nil }
+func (r *helloV2) UnmarshalRecord(b []byte) error  { r.Message = string(b); return nil }
+
+func TestRegisterVersionedAliasResolvesToLegacyType(t *testing.T) {
+	reg := &Registry{}
+	current := PayloadTypeFromString("/test/hello/v2")
+	legacy := PayloadTypeFromString("/test/hello/v1")
+
+	reg.RegisterVersioned(current, &helloV2{}, WithAliases(Alias{
+		PayloadType: legacy,
+		Prototype:   &helloV1{},
+	}))
+
+	rec, err := reg.BlankRecordForPayloadType(legacy)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if _, ok := rec.(*helloV1); !ok {
+		t.Fatalf("expected alias to resolve to its own legacy type *helloV1, got %T", rec)
+	}
+
+	// The canonical payload type must still resolve to the current type.
+	rec, err = reg.BlankRecordForPayloadType(current)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if _, ok := rec.(*helloV2); !ok {
+		t.Fatalf("expected current payload type to resolve to *helloV2, got %T", rec)
+	}
+}
+
+func TestUnmarshalRecordPayloadInvokesMigratorOnlyOnAliasHit(t *testing.T) {
+	reg := &Registry{}
+	current := PayloadTypeFromString("/test/hello/v2")
+	legacy := PayloadTypeFromString("/test/hello/v1")
+
+	migrated := false
+	reg.RegisterVersioned(current, &helloV2{},
+		WithAliases(Alias{
+			PayloadType: legacy,
+			Prototype:   &helloV1{},
+		}),
+		WithMigrator(func(old Record) (Record, error) {
+			migrated = true
+			v1, ok := old.(*helloV1)
+			if !ok {
+				return nil, errors.New("unexpected record type passed to migrator")
+			}
+			return &helloV2{Message: v1.Text, Upgraded: true}, nil
+		}),
+	)
+
+	// Unmarshaling the canonical payload type must not invoke the migrator.
+	rec, err := reg.UnmarshalRecordPayload(current, []byte("hi"))
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if migrated {
+		t.Fatalf("migrator should not run when unmarshaling the canonical payload type")
+	}
+	if rec.(*helloV2).Upgraded {
+		t.Fatalf("canonical-type record should not be marked upgraded")
+	}
+
+	// Unmarshaling the legacy alias must decode with the legacy type and
+	// invoke the migrator to produce the current type.
+	rec, err = reg.UnmarshalRecordPayload(legacy, []byte("hi"))
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if !migrated {
+		t.Fatalf("expected migrator to run when unmarshaling a legacy alias")
+	}
+	v2, ok := rec.(*helloV2)
+	if !ok {
+		t.Fatalf("expected *helloV2 from migrator, got %T", rec)
+	}
+	if !v2.Upgraded || v2.Message != "hi" {
+		t.Fatalf("expected migrated record with Message %q and Upgraded=true, got %+v", "hi", v2)
+	}
+}
+
+func TestRegisterVersionedWithoutAliasesBehavesLikeRegister(t *testing.T) {
+	reg := &Registry{}
+	pt := PayloadTypeFromString("/test/hello/v1")
+
+	reg.RegisterVersioned(pt, &helloV1{})
+
+	if !reg.PayloadTypeRegistered(pt) {
+		t.Fatalf("expected %q to be registered", pt)
+	}
+}